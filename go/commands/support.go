@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"goweb/go/support"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Support exposes support-related subcommands under `goweb support`.
+var Support = &cli.Command{
+	Name:  "support",
+	Usage: "diagnostics to help with bug reports",
+	Commands: []*cli.Command{
+		{
+			Name:  "dump",
+			Usage: "bundle logs, config, and runtime info into a diagnostic zip",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "output",
+					Aliases:  []string{"o"},
+					Usage:    "zip file to write, or '-' for stdout",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "redact",
+					Usage: "comma-separated list of config keys to redact",
+				},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				output := cmd.String("output")
+				opts := support.Options{Redact: support.ParseRedact(cmd.String("redact"))}
+
+				if output == "-" {
+					return support.Dump(ctx, os.Stdout, opts)
+				}
+
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+
+				if err := support.Dump(ctx, f, opts); err != nil {
+					f.Close()
+					os.Remove(output) // don't leave a partial, invalid zip behind
+					return err
+				}
+				f.Close()
+				fmt.Printf("Wrote diagnostic bundle to %s\n", output)
+				return nil
+			},
+		},
+	},
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"goweb/go/database/config"
+	"goweb/go/update"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Update exposes update-related subcommands under `goweb update`.
+var Update = &cli.Command{
+	Name:  "update",
+	Usage: "check for and manage gotest updates",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "channel",
+			Usage: "update channel for 'install' (stable|beta|nightly), overrides updateChannel config; check and rollback always use the config value",
+		},
+		&cli.StringFlag{
+			Name:  "pubkey",
+			Usage: "override the baked-in manifest public key (hex) for 'install', for testing",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "print what would happen without downloading or installing anything",
+		},
+	},
+	Commands: []*cli.Command{
+		{
+			Name:  "check",
+			Usage: "check whether a newer version is available",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				available, err := update.Check(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to check for updates: %w", err)
+				}
+				if available {
+					fmt.Println("An update is available. Run 'goweb update install' to apply it.")
+				} else {
+					fmt.Println("You're on the latest version for your channel.")
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "install",
+			Usage: "download and install the latest version on the configured channel",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				channel, pubKey, err := resolveChannelAndKey(ctx, cmd)
+				if err != nil {
+					return err
+				}
+				m, err := update.Resolve(ctx, channel, pubKey)
+				if err != nil {
+					return fmt.Errorf("failed to resolve manifest: %w", err)
+				}
+				return update.Install(ctx, m, cmd.Bool("dry-run"))
+			},
+		},
+		{
+			Name:  "channels",
+			Usage: "list available update channels",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				fmt.Println(strings.Join(update.Channels, "\n"))
+				return nil
+			},
+		},
+		{
+			Name:      "pin",
+			Usage:     "lock the install to a specific version",
+			ArgsUsage: "<version>",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				v := cmd.Args().First()
+				if v == "" {
+					return fmt.Errorf("usage: goweb update pin <version>")
+				}
+				if err := update.Pin(ctx, v); err != nil {
+					return fmt.Errorf("failed to pin version: %w", err)
+				}
+				fmt.Printf("Pinned to %s. Run 'goweb update unpin' to unpin.\n", v)
+				return nil
+			},
+		},
+		{
+			Name:  "unpin",
+			Usage: "clear a version pin set with 'goweb update pin'",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if err := update.Unpin(ctx); err != nil {
+					return fmt.Errorf("failed to unpin version: %w", err)
+				}
+				fmt.Println("Unpinned. Updates will resume on the configured channel.")
+				return nil
+			},
+		},
+		{
+			Name:  "rollback",
+			Usage: "reinstall the version running before the most recent update",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return update.Rollback(ctx, cmd.Bool("dry-run"))
+			},
+		},
+	},
+}
+
+// resolveChannelAndKey applies --channel/--pubkey overrides on top of the
+// persisted config.
+func resolveChannelAndKey(ctx context.Context, cmd *cli.Command) (string, string, error) {
+	channel := cmd.String("channel")
+	if channel == "" {
+		c, err := config.Get[string](ctx, "updateChannel")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read update channel: %w", err)
+		}
+		channel = c
+	}
+	pubKey := cmd.String("pubkey")
+	if pubKey == "" {
+		pubKey = update.PublicKeyHex
+	}
+	return channel, pubKey, nil
+}
@@ -0,0 +1,128 @@
+// Package config stores gotest's runtime configuration in the "config"
+// bucket of the database, with in-memory defaults for any key that hasn't
+// been persisted yet. Values round-trip through JSON so Get is generic over
+// the expected type.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"goweb/go/database"
+	"goweb/go/logging"
+)
+
+// Bucket is the database bucket config is stored under. Exported so callers
+// that need a value before config.Init has run (e.g. main.run's early
+// filesystem check) can read it with the same key a normal Get would use.
+const Bucket = "config"
+
+const bucket = Bucket
+
+type ctxKey struct{}
+
+type store struct {
+	mu   sync.RWMutex
+	data map[string]json.RawMessage
+	db   *database.DB
+}
+
+// defaults are the values gotest ships with until a user overrides them.
+func defaults() map[string]any {
+	return map[string]any{
+		"logLevel":             "warn",
+		"logFormat":            "text",
+		"logRetentionDays":     7,
+		"logLevels":            map[string]string{},
+		"updateNotify":         true,
+		"updateChannel":        "stable",
+		"lastUpdateCheck":      time.Time{}.Format(time.RFC3339),
+		"shutdownTimeout":      10,
+		"datapath.fsAllowlist": []string{},
+	}
+}
+
+// Init loads persisted config from the database (falling back to defaults
+// for any key not yet stored) and returns a context carrying the store.
+func Init(ctx context.Context) (context.Context, error) {
+	db, ok := database.FromContext(ctx)
+	if !ok {
+		return ctx, fmt.Errorf("config.Init: no database in context")
+	}
+
+	s := &store{data: map[string]json.RawMessage{}, db: db}
+	for k, v := range defaults() {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return ctx, fmt.Errorf("failed to marshal default for %q: %w", k, err)
+		}
+		s.data[k] = raw
+	}
+	if err := db.ForEach(bucket, func(key, value []byte) error {
+		s.data[string(key)] = append(json.RawMessage(nil), value...)
+		return nil
+	}); err != nil {
+		return ctx, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logging.Debug(logging.WithSubsystem(ctx, "config"), "loaded config", "keys", len(s.data))
+	return context.WithValue(ctx, ctxKey{}, s), nil
+}
+
+// Get returns the config value stored under key, unmarshaled as T.
+func Get[T any](ctx context.Context, key string) (T, error) {
+	var zero T
+	s, ok := ctx.Value(ctxKey{}).(*store)
+	if !ok {
+		return zero, fmt.Errorf("config.Get: config not initialized in context")
+	}
+	s.mu.RLock()
+	raw, ok := s.data[key]
+	s.mu.RUnlock()
+	if !ok {
+		return zero, fmt.Errorf("config key %q not set", key)
+	}
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return zero, fmt.Errorf("config key %q is not a %T: %w", key, zero, err)
+	}
+	return v, nil
+}
+
+// Set persists value under key, both in memory and to the database.
+func Set(ctx context.Context, key string, value any) error {
+	s, ok := ctx.Value(ctxKey{}).(*store)
+	if !ok {
+		return fmt.Errorf("config.Set: config not initialized in context")
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config value for %q: %w", key, err)
+	}
+	if err := s.db.Put(bucket, key, raw); err != nil {
+		return fmt.Errorf("failed to persist config key %q: %w", key, err)
+	}
+	s.mu.Lock()
+	s.data[key] = raw
+	s.mu.Unlock()
+	return nil
+}
+
+// All returns a snapshot of the effective config as a map of key to raw
+// JSON value, useful for dumping the current configuration verbatim.
+func All(ctx context.Context) (map[string]json.RawMessage, error) {
+	s, ok := ctx.Value(ctxKey{}).(*store)
+	if !ok {
+		return nil, fmt.Errorf("config.All: config not initialized in context")
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]json.RawMessage, len(s.data))
+	for k, v := range s.data {
+		out[k] = append(json.RawMessage(nil), v...)
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+// Package database wraps the embedded bolt store gotest uses for config and
+// local state. Everything else (config, support dump, etc.) talks to it
+// through the small bucket-scoped API below rather than touching bbolt
+// directly.
+package database
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"goweb/go/database/datapath"
+	"goweb/go/logging"
+
+	"go.etcd.io/bbolt"
+)
+
+type ctxKey struct{}
+
+// DB is a thin handle around the bolt file backing gotest's local state.
+type DB struct {
+	bolt *bbolt.DB
+	path string
+}
+
+// New opens (creating if necessary) the bolt database under the data path
+// stored in ctx.
+func New(ctx context.Context) (*DB, error) {
+	dataPath, ok := datapath.FromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("database.New: no data path in context")
+	}
+	path := filepath.Join(dataPath, "gotest.db")
+	b, err := bbolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt db at %s: %w", path, err)
+	}
+	logging.Debug(logging.WithSubsystem(ctx, "database"), "opened bolt db", "path", path)
+	return &DB{bolt: b, path: path}, nil
+}
+
+// IntoContext attaches db to ctx.
+func IntoContext(ctx context.Context, db *DB) context.Context {
+	return context.WithValue(ctx, ctxKey{}, db)
+}
+
+// FromContext returns the *DB stored by IntoContext and whether one was set.
+func FromContext(ctx context.Context) (*DB, bool) {
+	db, ok := ctx.Value(ctxKey{}).(*DB)
+	return db, ok
+}
+
+// Path returns the on-disk path of the bolt file.
+func (db *DB) Path() string { return db.path }
+
+// Close closes the underlying bolt file.
+func (db *DB) Close() error { return db.bolt.Close() }
+
+// Put stores value under key in bucket, creating the bucket if needed.
+func (db *DB) Put(bucket, key string, value []byte) error {
+	return db.bolt.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), value)
+	})
+}
+
+// Get returns the value stored under key in bucket, or (nil, false) if it
+// doesn't exist.
+func (db *DB) Get(bucket, key string) ([]byte, bool) {
+	var value []byte
+	_ = db.bolt.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		if v := b.Get([]byte(key)); v != nil {
+			value = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return value, value != nil
+}
+
+// ForEach iterates every key/value pair in bucket in key order. It's a
+// no-op if the bucket doesn't exist.
+func (db *DB) ForEach(bucket string, fn func(key, value []byte) error) error {
+	return db.bolt.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(fn)
+	})
+}
+
+// Stats returns the bolt file's runtime stats, useful for diagnostics.
+func (db *DB) Stats() bbolt.Stats { return db.bolt.Stats() }
@@ -0,0 +1,19 @@
+// Package datapath carries the resolved data directory path through
+// context so packages below main don't need it threaded through every
+// function signature.
+package datapath
+
+import "context"
+
+type ctxKey struct{}
+
+// IntoContext attaches the resolved data path to ctx.
+func IntoContext(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, path)
+}
+
+// FromContext returns the data path stored by IntoContext and whether one was set.
+func FromContext(ctx context.Context) (string, bool) {
+	p, ok := ctx.Value(ctxKey{}).(string)
+	return p, ok
+}
@@ -0,0 +1,37 @@
+//go:build darwin || freebsd
+
+package datapath
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// detect uses statfs(2)'s f_fstypename, which both darwin and freebsd
+// populate with a short human-readable name (e.g. "apfs", "msdos", "nfs"),
+// unlike Linux's f_type magic number.
+func detect(path string) (FSInfo, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return FSInfo{Path: path}, err
+	}
+
+	fsType := normalizeType(byteArrayToString(st.Fstypename[:]))
+	info := FSInfo{Path: path, Type: fsType, Durable: !unsafeFS[fsType]}
+	if warnFS[fsType] {
+		info.Warning = fmt.Sprintf("data path is on %s, which can silently lose writes on network partition", fsType)
+	}
+	return info, nil
+}
+
+// byteArrayToString converts a NUL-terminated (or full-length) []byte, as
+// golang.org/x/sys/unix represents Statfs_t.Fstypename on both darwin and
+// freebsd, into a Go string.
+func byteArrayToString(raw []byte) string {
+	if i := bytes.IndexByte(raw, 0); i >= 0 {
+		raw = raw[:i]
+	}
+	return string(raw)
+}
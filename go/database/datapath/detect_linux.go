@@ -0,0 +1,52 @@
+//go:build linux
+
+package datapath
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// magic numbers from linux/magic.h
+const (
+	magicExt       = 0xEF53
+	magicXFS       = 0x58465342
+	magicBtrfs     = 0x9123683E
+	magicZFS       = 0x2FC12FC1
+	magicTmpfs     = 0x01021994
+	magicNFS       = 0x6969
+	magicMsdos     = 0x4d44
+	magicOverlayFS = 0x794c7630
+)
+
+var linuxFSTypes = map[int64]string{
+	magicExt:       "ext4",
+	magicXFS:       "xfs",
+	magicBtrfs:     "btrfs",
+	magicZFS:       "zfs",
+	magicTmpfs:     "tmpfs",
+	magicNFS:       "nfs",
+	magicMsdos:     "fat",
+	magicOverlayFS: "overlayfs",
+}
+
+// detect statfs(2)'s path and maps its f_type magic number to a friendly
+// filesystem name, classifying it as durable/warn/unsafe along the way.
+func detect(path string) (FSInfo, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return FSInfo{Path: path}, err
+	}
+
+	fsType, ok := linuxFSTypes[int64(st.Type)]
+	if !ok {
+		fsType = "unknown"
+	}
+
+	info := FSInfo{Path: path, Type: fsType, Durable: !unsafeFS[fsType]}
+	if warnFS[fsType] {
+		info.Warning = fmt.Sprintf("data path is on %s, which can silently lose writes on network partition", fsType)
+	}
+	return info, nil
+}
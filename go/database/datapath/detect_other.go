@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package datapath
+
+// detect is the fallback for platforms with no dedicated detector wired up
+// (see detect_bsd.go, detect_windows.go): there's no syscall we know how to
+// use, so we report "unknown" and let durability checks pass by default
+// rather than false-failing on platforms we haven't implemented yet.
+func detect(path string) (FSInfo, error) {
+	return FSInfo{Path: path, Type: "unknown", Durable: true}, nil
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package datapath
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// detect resolves path to its volume root and reads the filesystem name
+// (e.g. "NTFS", "FAT32", "ReFS") via GetVolumeInformation.
+func detect(path string) (FSInfo, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return FSInfo{Path: path}, err
+	}
+
+	var rootBuf [windows.MAX_PATH + 1]uint16
+	if err := windows.GetVolumePathName(pathPtr, &rootBuf[0], uint32(len(rootBuf))); err != nil {
+		return FSInfo{Path: path}, err
+	}
+
+	var fsNameBuf [64]uint16
+	if err := windows.GetVolumeInformation(
+		&rootBuf[0],
+		nil, 0,
+		nil, nil, nil,
+		&fsNameBuf[0], uint32(len(fsNameBuf)),
+	); err != nil {
+		return FSInfo{Path: path}, err
+	}
+
+	fsType := normalizeType(windows.UTF16ToString(fsNameBuf[:]))
+	info := FSInfo{Path: path, Type: fsType, Durable: !unsafeFS[fsType]}
+	if warnFS[fsType] {
+		info.Warning = fmt.Sprintf("data path is on %s, which can silently lose writes on network partition", fsType)
+	}
+	return info, nil
+}
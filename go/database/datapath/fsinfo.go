@@ -0,0 +1,92 @@
+package datapath
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"goweb/go/logging"
+)
+
+// FSInfo records what filesystem the data path lives on and whether gotest
+// considers it durable enough for the embedded database's fsync guarantees.
+type FSInfo struct {
+	Path    string
+	Type    string // e.g. "ext4", "tmpfs", "nfs", "unknown"
+	Durable bool   // false for filesystems unsuitable for fsync-dependent storage
+	Warning string // non-empty if Durable but still worth flagging (e.g. nfs)
+}
+
+// unsafeFS lists filesystem types that risk silent data loss for a database
+// that relies on fsync, and are refused unless explicitly allowed. Keys must
+// match the type strings detect() actually produces (see linuxFSTypes).
+var unsafeFS = map[string]bool{
+	"tmpfs": true,
+	"fat":   true,
+}
+
+// warnFS lists filesystem types that are durable but have known footguns
+// (e.g. weak consistency on network partition) worth a warning.
+var warnFS = map[string]bool{
+	"nfs": true,
+}
+
+type fsInfoCtxKey struct{}
+
+// IntoContextFS attaches detected filesystem info to ctx.
+func IntoContextFS(ctx context.Context, info FSInfo) context.Context {
+	return context.WithValue(ctx, fsInfoCtxKey{}, info)
+}
+
+// FromContextFS returns the FSInfo stored by IntoContextFS and whether one was set.
+func FromContextFS(ctx context.Context) (FSInfo, bool) {
+	info, ok := ctx.Value(fsInfoCtxKey{}).(FSInfo)
+	return info, ok
+}
+
+// Validate detects the filesystem backing path and refuses to proceed if
+// it's unsuitable for durable storage, unless allowUnsafe is set or the
+// detected type appears in allowlist. It returns the detected info either
+// way so callers can record it (e.g. in the support bundle) even when
+// overridden.
+func Validate(ctx context.Context, path string, allowUnsafe bool, allowlist []string) (FSInfo, error) {
+	info, err := detect(path)
+	if err != nil {
+		return info, fmt.Errorf("failed to detect filesystem for %s: %w", path, err)
+	}
+
+	if !info.Durable && !allowUnsafe && !allowed(info.Type, allowlist) {
+		return info, fmt.Errorf(
+			"data path %s is on a %s filesystem, which isn't safe for durable storage; "+
+				"pass --allow-unsafe-fs or add %q to datapath.fsAllowlist to override",
+			path, info.Type, info.Type,
+		)
+	}
+	if info.Warning != "" {
+		logging.Debug(logging.WithSubsystem(ctx, "datapath"), info.Warning, "type", info.Type, "path", path)
+	}
+	return info, nil
+}
+
+// normalizeType maps the various names different OSes/drivers report for
+// the same filesystem family (e.g. darwin's "msdos", Windows' "FAT32") onto
+// the canonical keys unsafeFS/warnFS use.
+func normalizeType(raw string) string {
+	switch strings.ToLower(raw) {
+	case "msdos", "vfat", "fat32", "fat16", "exfat":
+		return "fat"
+	case "nfs", "nfs4":
+		return "nfs"
+	default:
+		return strings.ToLower(raw)
+	}
+}
+
+func allowed(fsType string, allowlist []string) bool {
+	for _, t := range allowlist {
+		if t == fsType {
+			return true
+		}
+	}
+	return false
+}
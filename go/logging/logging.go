@@ -0,0 +1,294 @@
+// Package logging adds structured output, size-based rotation, and
+// per-subsystem levels on top of the plain xlog logger main wires up at
+// startup. It's a separate, local package rather than an extension of
+// github.com/Data-Corruption/stdx/xlog: that's a third-party dependency this
+// repo doesn't own, and it has no support for JSON formatting, rotation, or
+// per-subsystem levels itself. xlog is still used directly for the simple
+// top-level messages and error reporting it already handles (see main.go);
+// this package is for the richer, subsystem-tagged entries layered on top.
+package logging
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type subsystemKey struct{}
+
+// WithSubsystem tags ctx with a subsystem name (e.g. "database", "update"),
+// used both to label entries a Logger writes and to pick that subsystem's
+// configured level.
+func WithSubsystem(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, subsystemKey{}, name)
+}
+
+// Subsystem returns the subsystem name tagged on ctx by WithSubsystem, or ""
+// if none was set.
+func Subsystem(ctx context.Context) string {
+	name, _ := ctx.Value(subsystemKey{}).(string)
+	return name
+}
+
+// levelRank orders levels from most to least verbose; "none" disables
+// logging entirely.
+var levelRank = map[string]int{
+	"debug": 0,
+	"info":  1,
+	"warn":  2,
+	"error": 3,
+	"none":  4,
+}
+
+// Config controls how a Logger formats, filters, and rotates entries.
+type Config struct {
+	Format        string            // "text" or "json"
+	DefaultLevel  string            // level for subsystems not named in Levels
+	Levels        map[string]string // per-subsystem level overrides
+	MaxSizeBytes  int64             // rotate the current file once it passes this size
+	RetentionDays int               // Prune deletes rotated archives older than this
+}
+
+type ctxKey struct{}
+
+// IntoContext attaches l to ctx.
+func IntoContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the Logger stored by IntoContext and whether one was set.
+func FromContext(ctx context.Context) (*Logger, bool) {
+	l, ok := ctx.Value(ctxKey{}).(*Logger)
+	return l, ok
+}
+
+// Debug writes a debug-level entry via the Logger stored in ctx, if any. It's
+// a no-op if ctx doesn't carry one, mirroring xlog.Debug's ctx-first style so
+// call sites don't need to thread a *Logger through separately.
+func Debug(ctx context.Context, msg string, kv ...any) {
+	if l, ok := FromContext(ctx); ok {
+		l.Debug(ctx, msg, kv...)
+	}
+}
+
+// Error writes an error-level entry via the Logger stored in ctx, if any.
+func Error(ctx context.Context, err error) {
+	if l, ok := FromContext(ctx); ok {
+		l.Error(ctx, err)
+	}
+}
+
+// Logger writes structured, subsystem-aware entries to a rotated file under
+// dir, independent of the plain xlog logger main also keeps.
+type Logger struct {
+	mu   sync.Mutex
+	dir  string
+	cfg  Config
+	file *os.File
+	size int64
+}
+
+// New opens (creating if necessary) the current structured log file under
+// dir.
+func New(dir string, cfg Config) (*Logger, error) {
+	l := &Logger{dir: dir, cfg: cfg}
+	if err := l.openCurrent(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Logger) openCurrent() error {
+	path := filepath.Join(l.dir, "structured.log")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	l.file = f
+	l.size = info.Size()
+	return nil
+}
+
+// Reconfigure updates cfg in place, e.g. once config has loaded and the
+// user's logFormat/logRetentionDays/logLevels keys are known.
+func (l *Logger) Reconfigure(cfg Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = cfg
+}
+
+// Close flushes and closes the current log file.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+func (l *Logger) levelFor(subsystem string) string {
+	if subsystem != "" {
+		if lvl, ok := l.cfg.Levels[subsystem]; ok {
+			return lvl
+		}
+	}
+	if l.cfg.DefaultLevel != "" {
+		return l.cfg.DefaultLevel
+	}
+	return "warn"
+}
+
+func (l *Logger) enabled(subsystem, level string) bool {
+	want, ok := levelRank[level]
+	if !ok {
+		want = levelRank["info"]
+	}
+	have, ok := levelRank[l.levelFor(subsystem)]
+	if !ok {
+		have = levelRank["warn"]
+	}
+	return want >= have
+}
+
+// Debug writes a debug-level entry tagged with ctx's subsystem, if that
+// subsystem's configured level allows debug output. kv is an alternating
+// key/value list, e.g. Debug(ctx, "opened bolt db", "path", path).
+func (l *Logger) Debug(ctx context.Context, msg string, kv ...any) {
+	l.write(ctx, "debug", msg, kv)
+}
+
+// Error writes an error-level entry tagged with ctx's subsystem. Errors are
+// written at every configured level except "none".
+func (l *Logger) Error(ctx context.Context, err error) {
+	l.write(ctx, "error", err.Error(), nil)
+}
+
+func (l *Logger) write(ctx context.Context, level, msg string, kv []any) {
+	subsystem := Subsystem(ctx)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.enabled(subsystem, level) {
+		return
+	}
+
+	line := l.format(subsystem, level, msg, kv)
+	n, err := l.file.WriteString(line)
+	if err != nil {
+		return // best-effort: a failed log write shouldn't crash the app
+	}
+	l.size += int64(n)
+	if l.cfg.MaxSizeBytes > 0 && l.size >= l.cfg.MaxSizeBytes {
+		l.rotateLocked()
+	}
+}
+
+func (l *Logger) format(subsystem, level, msg string, kv []any) string {
+	fields := make(map[string]string, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		fields[fmt.Sprintf("%v", kv[i])] = fmt.Sprintf("%v", kv[i+1])
+	}
+
+	if l.cfg.Format == "json" {
+		entry := struct {
+			Ts        string            `json:"ts"`
+			Level     string            `json:"level"`
+			Msg       string            `json:"msg"`
+			Subsystem string            `json:"subsystem,omitempty"`
+			Fields    map[string]string `json:"fields,omitempty"`
+		}{time.Now().UTC().Format(time.RFC3339Nano), level, msg, subsystem, fields}
+		b, err := json.Marshal(entry)
+		if err != nil {
+			return "" // drop malformed entries rather than crash the logger
+		}
+		return string(b) + "\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s [%s]", time.Now().UTC().Format(time.RFC3339), level)
+	if subsystem != "" {
+		fmt.Fprintf(&b, " (%s)", subsystem)
+	}
+	fmt.Fprintf(&b, " %s", msg)
+	for k, v := range fields {
+		fmt.Fprintf(&b, " %s=%s", k, v)
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// rotateLocked closes the current file, gzip-compresses it under a
+// timestamped name, and opens a fresh current file. Callers must hold l.mu.
+func (l *Logger) rotateLocked() {
+	curPath := filepath.Join(l.dir, "structured.log")
+	if err := l.file.Close(); err != nil {
+		return
+	}
+	archivePath := filepath.Join(l.dir, fmt.Sprintf("structured-%s.log.gz", time.Now().UTC().Format("20060102T150405")))
+	if err := gzipFile(curPath, archivePath); err == nil {
+		os.Remove(curPath)
+	}
+	f, err := os.OpenFile(curPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return
+	}
+	l.file = f
+	l.size = 0
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	gw := gzip.NewWriter(out)
+	defer gw.Close()
+	_, err = io.Copy(gw, in)
+	return err
+}
+
+// Prune removes rotated archives under dir older than the configured
+// RetentionDays. It's a no-op if RetentionDays isn't positive.
+func (l *Logger) Prune() error {
+	l.mu.Lock()
+	retentionDays := l.cfg.RetentionDays
+	dir := l.dir
+	l.mu.Unlock()
+	if retentionDays <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read log dir %s: %w", dir, err)
+	}
+	cutoff := time.Now().Add(-time.Duration(retentionDays) * 24 * time.Hour)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".log.gz") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogger_SubsystemLevelGating(t *testing.T) {
+	dir := t.TempDir()
+	l, err := New(dir, Config{Format: "text", DefaultLevel: "warn", Levels: map[string]string{"database": "debug"}})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	defer l.Close()
+
+	l.Debug(WithSubsystem(context.Background(), "database"), "should be written")
+	l.Debug(WithSubsystem(context.Background(), "update"), "should be dropped")
+
+	data, err := os.ReadFile(filepath.Join(dir, "structured.log"))
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "should be written") {
+		t.Fatalf("log = %q, want it to contain the debug entry for a subsystem configured at debug", data)
+	}
+	if strings.Contains(string(data), "should be dropped") {
+		t.Fatalf("log = %q, want the update subsystem's debug entry dropped at the default warn level", data)
+	}
+}
+
+func TestLogger_JSONFormat(t *testing.T) {
+	dir := t.TempDir()
+	l, err := New(dir, Config{Format: "json", DefaultLevel: "debug"})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	defer l.Close()
+
+	l.Debug(WithSubsystem(context.Background(), "config"), "loaded config", "keys", 3)
+
+	data, err := os.ReadFile(filepath.Join(dir, "structured.log"))
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	var entry struct {
+		Level     string            `json:"level"`
+		Msg       string            `json:"msg"`
+		Subsystem string            `json:"subsystem"`
+		Fields    map[string]string `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("failed to unmarshal JSON log line %q: %v", data, err)
+	}
+	if entry.Level != "debug" || entry.Msg != "loaded config" || entry.Subsystem != "config" || entry.Fields["keys"] != "3" {
+		t.Fatalf("entry = %+v, want level=debug msg=%q subsystem=config fields[keys]=3", entry, "loaded config")
+	}
+}
+
+func TestLogger_RotatesPastMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	l, err := New(dir, Config{Format: "text", DefaultLevel: "debug", MaxSizeBytes: 10})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	defer l.Close()
+
+	l.Debug(context.Background(), "this line alone exceeds the 10 byte threshold")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read log dir: %v", err)
+	}
+	var sawArchive bool
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".log.gz") {
+			sawArchive = true
+		}
+	}
+	if !sawArchive {
+		t.Fatalf("dir entries = %v, want a rotated .log.gz archive after exceeding MaxSizeBytes", entries)
+	}
+}
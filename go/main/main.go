@@ -3,10 +3,13 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -14,6 +17,8 @@ import (
 	"goweb/go/database"
 	"goweb/go/database/config"
 	"goweb/go/database/datapath"
+	"goweb/go/logging"
+	"goweb/go/shutdown"
 	"goweb/go/update"
 	"goweb/go/version"
 
@@ -30,6 +35,7 @@ const Name = "gotest" // root command name
 const (
 	DefaultLogLevel = "warn"
 	DataIndexPath   = "/var/lib/" + Name + "/index"
+	LogMaxSizeBytes = 50 * 1024 * 1024 // rotate a log file once it passes this size
 )
 
 var Version string // set by build script
@@ -39,7 +45,15 @@ func main() { os.Exit(run()) }
 func run() int {
 	// base context with interrupt/termination handling
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+
+	// shutdown coordinator: subsystems below register close hooks instead of
+	// deferring directly, so they run in a controlled order with a deadline
+	sc := shutdown.New()
+	ctx = shutdown.IntoContext(ctx, sc)
+	sc.Register(shutdown.Hook{
+		Name: "signal", Priority: 10, Timeout: 2 * time.Second,
+		Fn: func(context.Context) error { stop(); return nil },
+	})
 
 	// insert version for update stuff
 	ctx = version.IntoContext(ctx, Version)
@@ -71,7 +85,24 @@ func run() int {
 		return 1
 	}
 	ctx = xlog.IntoContext(ctx, log)
-	defer log.Close()
+	sc.Register(shutdown.Hook{
+		Name: "logger", Priority: 20, Timeout: 5 * time.Second,
+		Fn: func(context.Context) error { return log.Close() },
+	})
+
+	// structured logger: adds JSON formatting, rotation, and per-subsystem
+	// levels that xlog itself doesn't support (see package logging). It
+	// writes to its own file under logPath, separate from xlog's.
+	structuredLog, err := logging.New(logPath, logging.Config{DefaultLevel: DefaultLogLevel, MaxSizeBytes: LogMaxSizeBytes})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize structured logger: %s\n", err)
+		return 1
+	}
+	ctx = logging.IntoContext(ctx, structuredLog)
+	sc.Register(shutdown.Hook{
+		Name: "structured-logger", Priority: 20, Timeout: 5 * time.Second,
+		Fn: func(context.Context) error { return structuredLog.Close() },
+	})
 
 	// init database
 	db, err := database.New(ctx)
@@ -80,9 +111,29 @@ func run() int {
 		return 1
 	}
 	ctx = database.IntoContext(ctx, db)
-	defer db.Close()
+	sc.Register(shutdown.Hook{
+		Name: "database", Priority: 30, Timeout: 5 * time.Second,
+		Fn: func(context.Context) error { return db.Close() },
+	})
 	xlog.Debug(ctx, "Database initialized")
 
+	// validate the data path's filesystem before config.Init so a bad choice
+	// (tmpfs, fat, ...) is caught before anything relies on durable writes.
+	// datapath.fsAllowlist is read directly from the config bucket since
+	// full config isn't loaded yet at this point.
+	var fsAllowlist []string
+	if raw, ok := db.Get(config.Bucket, "datapath.fsAllowlist"); ok {
+		if err := json.Unmarshal(raw, &fsAllowlist); err != nil {
+			log.Error(fmt.Errorf("failed to parse stored datapath.fsAllowlist, ignoring it: %w", err))
+		}
+	}
+	fsInfo, err := datapath.Validate(ctx, dataPath, hasAllowUnsafeFS(os.Args), fsAllowlist)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	ctx = datapath.IntoContextFS(ctx, fsInfo)
+
 	// init config
 	ctx, err = config.Init(ctx)
 	if err != nil {
@@ -102,6 +153,12 @@ func run() int {
 		return 1
 	}
 
+	// apply structured logging settings now that config is available
+	if err := configureLogging(ctx, structuredLog); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to configure logging: %s\n", err)
+		return 1
+	}
+
 	// Update check
 	updateNotify, err := config.Get[bool](ctx, "updateNotify")
 	if err != nil {
@@ -158,9 +215,14 @@ func run() int {
 				Aliases: []string{"y"},
 				Usage:   "answer yes to all prompts",
 			},
+			&cli.BoolFlag{
+				Name:  "allow-unsafe-fs",
+				Usage: "allow the data path to live on a filesystem gotest considers unsafe (tmpfs, fat, ...)",
+			},
 		},
 		Commands: []*cli.Command{
 			commands.Update,
+			commands.Support,
 		},
 		Before: func(ctx context.Context, cmd *cli.Command) (context.Context, error) {
 			logLevel := cmd.String("log")
@@ -174,14 +236,73 @@ func run() int {
 	}
 
 	// run app
-	if err := app.Run(ctx, os.Args); err != nil {
-		log.Error(err)
-		fmt.Fprintln(os.Stderr, err)
+	appErr := app.Run(ctx, os.Args)
+	if appErr != nil {
+		log.Error(appErr)
+		fmt.Fprintln(os.Stderr, appErr)
+	}
+
+	// run shutdown hooks (db, logger, signal release) against the configured
+	// deadline; a second interrupt during this window escalates to an
+	// immediate non-zero exit instead of waiting out the deadline
+	shutdownTimeoutSec, err := config.Get[int](ctx, "shutdownTimeout")
+	if err != nil {
+		shutdownTimeoutSec = 10
+	}
+	if code := sc.RunWithEscalation(ctx, time.Duration(shutdownTimeoutSec)*time.Second); code != 0 {
+		return code
+	}
+	if appErr != nil {
 		return 1
 	}
 	return 0
 }
 
+// configureLogging applies the logFormat, logRetentionDays, and logLevels
+// config keys to log, now that config is available, and prunes any archives
+// already past the configured retention.
+func configureLogging(ctx context.Context, log *logging.Logger) error {
+	format, err := config.Get[string](ctx, "logFormat")
+	if err != nil {
+		return fmt.Errorf("failed to get logFormat from config: %w", err)
+	}
+	retentionDays, err := config.Get[int](ctx, "logRetentionDays")
+	if err != nil {
+		return fmt.Errorf("failed to get logRetentionDays from config: %w", err)
+	}
+	levels, err := config.Get[map[string]string](ctx, "logLevels")
+	if err != nil {
+		return fmt.Errorf("failed to get logLevels from config: %w", err)
+	}
+
+	log.Reconfigure(logging.Config{
+		Format:        format,
+		DefaultLevel:  DefaultLogLevel,
+		Levels:        levels,
+		MaxSizeBytes:  LogMaxSizeBytes,
+		RetentionDays: retentionDays,
+	})
+	return log.Prune()
+}
+
+// hasAllowUnsafeFS reports whether --allow-unsafe-fs was passed. It's
+// scanned directly from args rather than through cli.Command since the
+// filesystem check runs before the app (and its flag parsing) is built, and
+// accepts the same "--flag", "--flag=true", and "--flag=false" forms
+// urfave/cli's own BoolFlag parsing does.
+func hasAllowUnsafeFS(args []string) bool {
+	for _, a := range args {
+		if a == "--allow-unsafe-fs" {
+			return true
+		}
+		if v, ok := strings.CutPrefix(a, "--allow-unsafe-fs="); ok {
+			b, err := strconv.ParseBool(v)
+			return err == nil && b
+		}
+	}
+	return false
+}
+
 func getDataPath() (string, error) {
 	if os.Geteuid() == 0 {
 		// root: read from index file
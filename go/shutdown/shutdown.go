@@ -0,0 +1,250 @@
+// Package shutdown coordinates graceful process termination: subsystems
+// register named close hooks with a priority and a per-hook timeout, and on
+// signal the coordinator runs them in reverse-priority order against an
+// overall deadline, escalating to an immediate exit if a second signal
+// arrives first.
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"goweb/go/logging"
+)
+
+// State describes where a Coordinator is in its lifecycle.
+type State int
+
+const (
+	Running State = iota
+	ShuttingDown
+	Escalated
+	Done
+)
+
+func (s State) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case ShuttingDown:
+		return "shutting down"
+	case Escalated:
+		return "escalated"
+	case Done:
+		return "done"
+	default:
+		return "unknown"
+	}
+}
+
+// EscalateExitCode is returned by Listen when a second signal forces an
+// immediate exit, matching the conventional 128+SIGINT code.
+const EscalateExitCode = 130
+
+// Hook is a named shutdown action. Hooks run in descending Priority order
+// (highest first) so, e.g., an HTTP server can stop accepting connections
+// before the database it depends on closes. Each hook is bounded by its own
+// Timeout in addition to the coordinator's overall deadline.
+type Hook struct {
+	Name     string
+	Priority int
+	Timeout  time.Duration
+	Fn       func(ctx context.Context) error
+}
+
+type ctxKey struct{}
+
+// Coordinator owns the registered hooks and the current shutdown state.
+type Coordinator struct {
+	mu    sync.Mutex
+	hooks []Hook
+	state State
+	once  sync.Once
+	done  chan struct{}
+}
+
+// New returns an empty Coordinator ready for hook registration.
+func New() *Coordinator {
+	return &Coordinator{done: make(chan struct{})}
+}
+
+// IntoContext attaches c to ctx.
+func IntoContext(ctx context.Context, c *Coordinator) context.Context {
+	return context.WithValue(ctx, ctxKey{}, c)
+}
+
+// FromContext returns the Coordinator stored by IntoContext and whether one was set.
+func FromContext(ctx context.Context) (*Coordinator, bool) {
+	c, ok := ctx.Value(ctxKey{}).(*Coordinator)
+	return c, ok
+}
+
+// Register adds a hook to be run on shutdown.
+func (c *Coordinator) Register(h Hook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, h)
+}
+
+// State returns the coordinator's current lifecycle state.
+func (c *Coordinator) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// Done returns a channel that's closed once shutdown begins, so long-running
+// handlers can select on it to abort cleanly.
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// WithDone returns a child of parent that's additionally canceled once
+// shutdown begins, so an operation holding only parent (e.g.
+// context.Background(), or a context that doesn't itself carry the process's
+// signal handling) can still abort promptly when Run starts.
+func (c *Coordinator) WithDone(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-c.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx
+}
+
+func (c *Coordinator) setState(s State) {
+	c.mu.Lock()
+	c.state = s
+	c.mu.Unlock()
+	if s == ShuttingDown {
+		c.once.Do(func() { close(c.done) })
+	}
+}
+
+// orderedHooks returns registered hooks sorted highest-priority-first.
+func (c *Coordinator) orderedHooks() []Hook {
+	c.mu.Lock()
+	hooks := append([]Hook(nil), c.hooks...)
+	c.mu.Unlock()
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].Priority > hooks[j].Priority })
+	return hooks
+}
+
+// Run executes every registered hook in priority order, each bounded by its
+// own timeout and by the overall deadline, logging each hook's outcome. It
+// returns a combined error describing any hooks that failed or timed out.
+// Once every hook has run, the coordinator's state moves to Done, unless
+// something (e.g. Listen escalating on a second signal) has already moved it
+// to Escalated.
+func (c *Coordinator) Run(ctx context.Context, overallTimeout time.Duration) error {
+	c.setState(ShuttingDown)
+	defer c.finish()
+
+	deadline := time.Now().Add(overallTimeout)
+	var errs []error
+	for _, h := range c.orderedHooks() {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			errs = append(errs, fmt.Errorf("hook %q: skipped, overall shutdown deadline exceeded", h.Name))
+			continue
+		}
+		timeout := remaining
+		if h.Timeout > 0 && h.Timeout < remaining {
+			timeout = h.Timeout
+		}
+
+		hctx, cancel := context.WithTimeout(ctx, timeout)
+		err := runHook(hctx, h)
+		cancel()
+
+		if err != nil {
+			logging.Error(logging.WithSubsystem(ctx, "shutdown"), fmt.Errorf("shutdown hook %q: %w", h.Name, err))
+			errs = append(errs, fmt.Errorf("hook %q: %w", h.Name, err))
+			continue
+		}
+		logging.Debug(logging.WithSubsystem(ctx, "shutdown"), "hook completed", "name", h.Name)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("shutdown completed with errors: %w", joinErrors(errs))
+	}
+	return nil
+}
+
+// finish moves the coordinator to its terminal Done state once Run
+// completes, unless it's already been escalated.
+func (c *Coordinator) finish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == ShuttingDown {
+		c.state = Done
+	}
+}
+
+// runHook runs h.Fn to completion, or reports a timeout if hctx expires first.
+func runHook(hctx context.Context, h Hook) error {
+	done := make(chan error, 1)
+	go func() { done <- h.Fn(hctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-hctx.Done():
+		return fmt.Errorf("timed out waiting for %q", h.Name)
+	}
+}
+
+// Listen blocks until ctx is done (the first SIGINT/SIGTERM, typically via
+// signal.NotifyContext), then hands off to RunWithEscalation.
+func (c *Coordinator) Listen(ctx context.Context, overallTimeout time.Duration) int {
+	<-ctx.Done()
+	return c.RunWithEscalation(ctx, overallTimeout)
+}
+
+// RunWithEscalation runs the registered hooks against overallTimeout,
+// starting immediately. If a second SIGINT/SIGTERM arrives before the hooks
+// finish, it escalates to an immediate exit code instead of waiting out the
+// deadline. Callers that already know shutdown should begin right away
+// (e.g. main, once the CLI app itself has returned) should call this
+// directly; Listen is for callers that still need to wait for the first
+// signal.
+func (c *Coordinator) RunWithEscalation(ctx context.Context, overallTimeout time.Duration) int {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	done := make(chan error, 1)
+	// WithoutCancel keeps ctx's values (the logger Run reports through) while
+	// dropping the cancellation the first signal has usually already fired.
+	go func() { done <- c.Run(context.WithoutCancel(ctx), overallTimeout) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			logging.Error(logging.WithSubsystem(ctx, "shutdown"), err)
+			return 1
+		}
+		return 0
+	case <-sigc:
+		c.setState(Escalated)
+		logging.Error(logging.WithSubsystem(ctx, "shutdown"), fmt.Errorf("second interrupt received, forcing immediate exit"))
+		return EscalateExitCode
+	}
+}
+
+// joinErrors merges errs into a single error whose message lists each one.
+func joinErrors(errs []error) error {
+	msg := errs[0].Error()
+	for _, err := range errs[1:] {
+		msg += "; " + err.Error()
+	}
+	return fmt.Errorf("%s", msg)
+}
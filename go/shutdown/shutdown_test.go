@@ -0,0 +1,138 @@
+package shutdown
+
+import (
+	"context"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRun_HungHookTimesOut(t *testing.T) {
+	c := New()
+	c.Register(Hook{
+		Name:     "hung",
+		Priority: 10,
+		Timeout:  20 * time.Millisecond,
+		Fn: func(ctx context.Context) error {
+			<-make(chan struct{}) // ignores cancellation, like a wedged handler
+			return nil
+		},
+	})
+
+	start := time.Now()
+	err := c.Run(context.Background(), time.Second)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected an error from a hook that never returns")
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Fatalf("Run took %s, want it to bail out around the hook's 20ms timeout", elapsed)
+	}
+	if c.State() != Done {
+		t.Fatalf("state = %s, want Done once Run has returned", c.State())
+	}
+}
+
+func TestRun_PriorityOrder(t *testing.T) {
+	c := New()
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	c.Register(Hook{Name: "database", Priority: 10, Timeout: time.Second, Fn: record("database")})
+	c.Register(Hook{Name: "http", Priority: 20, Timeout: time.Second, Fn: record("http")})
+	c.Register(Hook{Name: "logger", Priority: 5, Timeout: time.Second, Fn: record("logger")})
+
+	if err := c.Run(context.Background(), time.Second); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	want := []string{"http", "database", "logger"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i, name := range want {
+		if order[i] != name {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestRun_OverallDeadlineSkipsRemaining(t *testing.T) {
+	c := New()
+	c.Register(Hook{
+		Name:     "slow",
+		Priority: 10,
+		Timeout:  time.Second,
+		Fn: func(ctx context.Context) error {
+			time.Sleep(60 * time.Millisecond)
+			return nil
+		},
+	})
+	var ranSecond bool
+	c.Register(Hook{
+		Name:     "second",
+		Priority: 5,
+		Timeout:  time.Second,
+		Fn: func(ctx context.Context) error {
+			ranSecond = true
+			return nil
+		},
+	})
+
+	err := c.Run(context.Background(), 30*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected an error reporting the skipped hook")
+	}
+	if ranSecond {
+		t.Fatal("second hook ran despite the overall deadline already being exceeded")
+	}
+}
+
+func TestListen_EscalatesOnSecondSignal(t *testing.T) {
+	c := New()
+	c.Register(Hook{
+		Name:     "wedged",
+		Priority: 10,
+		Timeout:  10 * time.Second,
+		Fn: func(ctx context.Context) error {
+			<-ctx.Done() // never actually finishes within the test
+			<-make(chan struct{})
+			return nil
+		},
+	})
+
+	firstSignal, cancel := context.WithCancel(context.Background())
+	cancel() // simulate the first SIGINT already having fired
+
+	result := make(chan int, 1)
+	go func() { result <- c.Listen(firstSignal, 10*time.Second) }()
+
+	// give Listen time to register its signal.Notify before we send the second one
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatalf("failed to send second SIGINT: %v", err)
+	}
+
+	select {
+	case code := <-result:
+		if code != EscalateExitCode {
+			t.Fatalf("exit code = %d, want %d", code, EscalateExitCode)
+		}
+		if c.State() != Escalated {
+			t.Fatalf("state = %s, want Escalated", c.State())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Listen did not escalate after the second signal")
+	}
+}
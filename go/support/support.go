@@ -0,0 +1,172 @@
+// Package support assembles a diagnostic bundle (logs, effective config,
+// version/build info, runtime info, and DB stats) into a single zip so
+// users have a one-shot artifact to attach to bug reports.
+package support
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"goweb/go/database"
+	"goweb/go/database/config"
+	"goweb/go/database/datapath"
+	"goweb/go/version"
+)
+
+// Options controls what Dump includes and how it's sanitized.
+type Options struct {
+	// Redact lists config keys whose values should be replaced with
+	// "REDACTED" in the bundled config.json.
+	Redact []string
+}
+
+// Dump writes a diagnostic zip to w.
+func Dump(ctx context.Context, w io.Writer, opts Options) error {
+	zw := zip.NewWriter(w)
+
+	if err := addLogs(ctx, zw); err != nil {
+		return err
+	}
+	if err := addConfig(ctx, zw, opts.Redact); err != nil {
+		return err
+	}
+	if err := addVersionInfo(ctx, zw); err != nil {
+		return err
+	}
+	if err := addRuntimeInfo(ctx, zw); err != nil {
+		return err
+	}
+	if err := addDBStats(ctx, zw); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func addLogs(ctx context.Context, zw *zip.Writer) error {
+	dataPath, ok := datapath.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("support.Dump: no data path in context")
+	}
+	logDir := filepath.Join(dataPath, "logs")
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read log dir: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if err := addFile(zw, filepath.Join("logs", e.Name()), filepath.Join(logDir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addFile(zw *zip.Writer, nameInZip, srcPath string) error {
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", srcPath, err)
+	}
+	defer f.Close()
+
+	out, err := zw.Create(nameInZip)
+	if err != nil {
+		return fmt.Errorf("failed to create %s in bundle: %w", nameInZip, err)
+	}
+	if _, err := io.Copy(out, f); err != nil {
+		return fmt.Errorf("failed to write %s into bundle: %w", nameInZip, err)
+	}
+	return nil
+}
+
+func addConfig(ctx context.Context, zw *zip.Writer, redact []string) error {
+	all, err := config.All(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read effective config: %w", err)
+	}
+
+	redactSet := make(map[string]struct{}, len(redact))
+	for _, k := range redact {
+		redactSet[k] = struct{}{}
+	}
+
+	sanitized := make(map[string]json.RawMessage, len(all))
+	for k, v := range all {
+		if _, skip := redactSet[k]; skip {
+			sanitized[k] = json.RawMessage(`"REDACTED"`)
+			continue
+		}
+		sanitized[k] = v
+	}
+
+	return writeJSON(zw, "config.json", sanitized)
+}
+
+func addVersionInfo(ctx context.Context, zw *zip.Writer) error {
+	return writeJSON(zw, "version.json", map[string]string{
+		"version": version.FromContext(ctx),
+	})
+}
+
+func addRuntimeInfo(ctx context.Context, zw *zip.Writer) error {
+	dataPath, _ := datapath.FromContext(ctx)
+	fsInfo, _ := datapath.FromContextFS(ctx)
+	return writeJSON(zw, "runtime.json", map[string]any{
+		"goVersion":  runtime.Version(),
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+		"uid":        os.Geteuid(),
+		"dataPath":   dataPath,
+		"filesystem": fsInfo,
+	})
+}
+
+func addDBStats(ctx context.Context, zw *zip.Writer) error {
+	db, ok := database.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return writeJSON(zw, "db_stats.json", db.Stats())
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	out, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s in bundle: %w", name, err)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s into bundle: %w", name, err)
+	}
+	return nil
+}
+
+// ParseRedact splits a comma-separated --redact flag value into a key list.
+func ParseRedact(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	keys := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			keys = append(keys, p)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
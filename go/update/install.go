@@ -0,0 +1,141 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"goweb/go/database"
+	"goweb/go/database/config"
+	"goweb/go/shutdown"
+	"goweb/go/version"
+)
+
+const (
+	pinnedVersionKey = "pinnedVersion"
+	previousInstall  = "previousInstall"
+)
+
+// download streams url to a temp file alongside dest, verifying its sha256
+// digest against wantSHA256 before returning the temp file's path. The
+// caller is responsible for renaming it into place. ctx is checked both for
+// the request itself and on every chunk of the copy, so a shutdown in
+// progress aborts a multi-minute download instead of running it to completion.
+func download(ctx context.Context, url, dest, wantSHA256 string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request for %s: %w", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading %s: unexpected status %s", url, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".gotest-update-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer tmp.Close()
+
+	hash := sha256.New()
+	if err := copyWithContext(ctx, io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write download: %w", err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != wantSHA256 {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("checksum mismatch: got %s, want %s", got, wantSHA256)
+	}
+	if err := tmp.Chmod(0755); err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to set executable permissions: %w", err)
+	}
+	return tmp.Name(), nil
+}
+
+// copyWithContext copies src to dst in chunks, checking ctx between each one
+// so a cancellation (e.g. shutdown) stops the copy promptly even if the
+// connection itself hasn't noticed yet.
+func copyWithContext(ctx context.Context, dst io.Writer, src io.Reader) error {
+	buf := make([]byte, 32*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr != nil {
+			if readErr == io.EOF {
+				return nil
+			}
+			return readErr
+		}
+	}
+}
+
+// Install downloads and verifies the release described by m, then atomically
+// replaces the running binary with it. If dryRun is true, it logs what would
+// happen without downloading anything.
+func Install(ctx context.Context, m Manifest, dryRun bool) error {
+	if pinned, _ := config.Get[string](ctx, pinnedVersionKey); pinned != "" {
+		return fmt.Errorf("install is pinned to %s; run 'goweb update unpin' first", pinned)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate running binary: %w", err)
+	}
+
+	if dryRun {
+		fmt.Printf("dry-run: would install %s (channel %s) from %s over %s\n", m.Version, m.Channel, m.URL, exe)
+		return nil
+	}
+
+	// the download is long-running enough that it needs to abort on a
+	// shutdown even if ctx itself doesn't already carry that cancellation
+	// (e.g. a caller invoking Install with context.Background()).
+	if sc, ok := shutdown.FromContext(ctx); ok {
+		ctx = sc.WithDone(ctx)
+	}
+
+	tmp, err := download(ctx, m.URL, exe, m.SHA256)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	if err := recordPreviousVersion(ctx); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, exe); err != nil {
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+	return nil
+}
+
+// recordPreviousVersion stashes the currently running version so Rollback
+// can restore it later.
+func recordPreviousVersion(ctx context.Context) error {
+	db, ok := database.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("update: no database in context")
+	}
+	cur := version.FromContext(ctx)
+	if cur == "" {
+		return nil
+	}
+	return db.Put(bucket, previousInstall, []byte(cur))
+}
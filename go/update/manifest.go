@@ -0,0 +1,74 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Manifest describes a single published release on a channel.
+type Manifest struct {
+	Version        string `json:"version"`
+	Channel        string `json:"channel"`
+	URL            string `json:"url"`
+	SHA256         string `json:"sha256"`
+	MinVersion     string `json:"minVersion"`
+	RolloutPercent int    `json:"rolloutPercent"`
+	Sig            string `json:"sig"` // base64 ed25519 signature over the manifest with sig cleared
+}
+
+// verify checks m.Sig against pubKey, returning an error if it doesn't match.
+func (m Manifest) verify(pubKey ed25519.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(m.Sig)
+	if err != nil {
+		return fmt.Errorf("manifest signature is not valid base64: %w", err)
+	}
+	unsigned := m
+	unsigned.Sig = ""
+	payload, err := json.Marshal(unsigned)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest for verification: %w", err)
+	}
+	if !ed25519.Verify(pubKey, payload, sig) {
+		return fmt.Errorf("manifest signature verification failed")
+	}
+	return nil
+}
+
+// fetchManifest downloads and verifies the manifest for channel from baseURL.
+// It aborts early if ctx is canceled, e.g. by a shutdown in progress.
+func fetchManifest(ctx context.Context, baseURL, channel string, pubKeyHex string) (Manifest, error) {
+	var m Manifest
+
+	pubKey, err := hex.DecodeString(pubKeyHex)
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return m, fmt.Errorf("invalid update public key")
+	}
+
+	url := fmt.Sprintf("%s/%s/manifest.json", baseURL, channel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return m, fmt.Errorf("failed to build request for %s: %w", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return m, fmt.Errorf("failed to fetch manifest from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return m, fmt.Errorf("fetching manifest from %s: unexpected status %s", url, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return m, fmt.Errorf("failed to decode manifest from %s: %w", url, err)
+	}
+	if err := m.verify(ed25519.PublicKey(pubKey)); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
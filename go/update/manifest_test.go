@@ -0,0 +1,60 @@
+package update
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func signManifest(t *testing.T, priv ed25519.PrivateKey, m Manifest) Manifest {
+	t.Helper()
+	m.Sig = ""
+	payload, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	m.Sig = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, payload))
+	return m
+}
+
+func TestManifest_VerifyAcceptsValidSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	m := signManifest(t, priv, Manifest{Version: "1.2.3", Channel: "stable", URL: "https://example.com/gotest", SHA256: "abc", RolloutPercent: 100})
+
+	if err := m.verify(pub); err != nil {
+		t.Fatalf("verify() = %v, want nil", err)
+	}
+}
+
+func TestManifest_VerifyRejectsTamperedField(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	m := signManifest(t, priv, Manifest{Version: "1.2.3", Channel: "stable", URL: "https://example.com/gotest", SHA256: "abc", RolloutPercent: 100})
+
+	m.URL = "https://evil.example.com/gotest" // tampered after signing
+	if err := m.verify(pub); err == nil {
+		t.Fatal("verify() = nil, want error for a manifest tampered with after signing")
+	}
+}
+
+func TestManifest_VerifyRejectsWrongKey(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	m := signManifest(t, priv, Manifest{Version: "1.2.3", Channel: "stable", URL: "https://example.com/gotest", SHA256: "abc", RolloutPercent: 100})
+
+	if err := m.verify(otherPub); err == nil {
+		t.Fatal("verify() = nil, want error when verifying against a key that didn't sign it")
+	}
+}
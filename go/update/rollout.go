@@ -0,0 +1,97 @@
+package update
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"goweb/go/database"
+)
+
+const (
+	bucket       = "update"
+	installIDKey = "installID"
+)
+
+// installID returns this machine's stable per-install identifier, creating
+// and persisting one on first use.
+func installID(ctx context.Context) (string, error) {
+	db, ok := database.FromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("update: no database in context")
+	}
+	if v, ok := db.Get(bucket, installIDKey); ok {
+		return string(v), nil
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate install id: %w", err)
+	}
+	id := hex.EncodeToString(raw)
+	if err := db.Put(bucket, installIDKey, []byte(id)); err != nil {
+		return "", fmt.Errorf("failed to persist install id: %w", err)
+	}
+	return id, nil
+}
+
+// rolloutBucket maps id to a stable value in [0, 100), so a given machine
+// always lands in the same bucket for a given rolloutPercent gate.
+func rolloutBucket(id string) int {
+	sum := sha256.Sum256([]byte(id))
+	return int(binary.BigEndian.Uint32(sum[:4]) % 100)
+}
+
+// inRollout reports whether id falls within the first percent of buckets.
+func inRollout(id string, percent int) bool {
+	if percent >= 100 {
+		return true
+	}
+	if percent <= 0 {
+		return false
+	}
+	return rolloutBucket(id) < percent
+}
+
+// belowMinVersion reports whether current is older than min, comparing
+// dot-separated numeric components (e.g. "1.12.0" vs "1.9.3"). A missing or
+// non-numeric component compares as 0, so "1.2" is below "1.2.1". An empty
+// current (e.g. in tests or dev builds with no version baked in) is never
+// considered below min, since there's nothing meaningful to compare.
+func belowMinVersion(current, min string) bool {
+	if current == "" {
+		return false
+	}
+	cur := versionParts(current)
+	want := versionParts(min)
+	for i := 0; i < len(cur) || i < len(want); i++ {
+		var c, w int
+		if i < len(cur) {
+			c = cur[i]
+		}
+		if i < len(want) {
+			w = want[i]
+		}
+		if c != w {
+			return c < w
+		}
+	}
+	return false
+}
+
+// versionParts splits a dotted version string (with an optional leading "v")
+// into its numeric components.
+func versionParts(v string) []int {
+	fields := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	parts := make([]int, len(fields))
+	for i, f := range fields {
+		n, _ := strconv.Atoi(f)
+		parts[i] = n
+	}
+	return parts
+}
@@ -0,0 +1,62 @@
+package update
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRolloutBucket_Deterministic(t *testing.T) {
+	id := "11111111111111111111111111111111"
+	want := rolloutBucket(id)
+	for i := 0; i < 100; i++ {
+		if got := rolloutBucket(id); got != want {
+			t.Fatalf("rolloutBucket(%q) = %d, want stable %d across repeated calls", id, got, want)
+		}
+	}
+}
+
+func TestRolloutBucket_SpreadsAcrossIDs(t *testing.T) {
+	seen := map[int]bool{}
+	for i := 0; i < 50; i++ {
+		seen[rolloutBucket(fmt.Sprintf("install-%d", i))] = true
+	}
+	if len(seen) < 10 {
+		t.Fatalf("rolloutBucket produced only %d distinct buckets across 50 ids, want a reasonable spread", len(seen))
+	}
+}
+
+func TestInRollout_Boundaries(t *testing.T) {
+	id := "stable-test-id"
+	if inRollout(id, 0) {
+		t.Fatal("inRollout(id, 0) = true, want false")
+	}
+	if !inRollout(id, 100) {
+		t.Fatal("inRollout(id, 100) = false, want true")
+	}
+	bucket := rolloutBucket(id)
+	if inRollout(id, bucket) {
+		t.Fatalf("inRollout(id, %d) = true, want false when percent equals the id's own bucket", bucket)
+	}
+	if !inRollout(id, bucket+1) {
+		t.Fatalf("inRollout(id, %d) = false, want true when percent is one above the id's own bucket", bucket+1)
+	}
+}
+
+func TestBelowMinVersion(t *testing.T) {
+	cases := []struct {
+		current, min string
+		want         bool
+	}{
+		{"1.2.3", "1.2.0", false},
+		{"1.2.0", "1.2.3", true},
+		{"1.2", "1.2.1", true},
+		{"2.0.0", "1.9.9", false},
+		{"1.9.9", "2.0.0", true},
+		{"", "1.0.0", false},
+	}
+	for _, c := range cases {
+		if got := belowMinVersion(c.current, c.min); got != c.want {
+			t.Errorf("belowMinVersion(%q, %q) = %v, want %v", c.current, c.min, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package update checks for, verifies, and applies new releases of gotest
+// across stable/beta/nightly channels, using signed manifests and a
+// per-install staged rollout so a given machine consistently sees the same
+// rollout bucket.
+package update
+
+import (
+	"context"
+	"fmt"
+
+	"goweb/go/database"
+	"goweb/go/database/config"
+	"goweb/go/logging"
+	"goweb/go/version"
+)
+
+// Channels lists the supported update channels, from most to least stable.
+var Channels = []string{"stable", "beta", "nightly"}
+
+// PublicKeyHex is the Ed25519 public key (hex-encoded) manifests are signed
+// against. It's baked in at build time; --pubkey overrides it for testing.
+var PublicKeyHex string
+
+// ManifestBaseURL is the base URL manifests are fetched from, e.g.
+// "https://updates.example.com/gotest". Configurable for testing/mirrors.
+var ManifestBaseURL = "https://updates.gotest.dev"
+
+func validChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve fetches and verifies the manifest for channel. It returns the
+// manifest whether or not it's newer than the running version; it doesn't
+// consult a pinned version or the rollout gate.
+func Resolve(ctx context.Context, channel, pubKeyHex string) (Manifest, error) {
+	if !validChannel(channel) {
+		return Manifest{}, fmt.Errorf("unknown update channel %q", channel)
+	}
+	return fetchManifest(ctx, ManifestBaseURL, channel, pubKeyHex)
+}
+
+// Check reports whether a newer, rollout-eligible version than the one
+// running is available on the configured channel.
+func Check(ctx context.Context) (bool, error) {
+	channel, err := config.Get[string](ctx, "updateChannel")
+	if err != nil {
+		return false, fmt.Errorf("failed to read update channel: %w", err)
+	}
+	pinned, _ := config.Get[string](ctx, pinnedVersionKey)
+
+	logging.Debug(logging.WithSubsystem(ctx, "update"), "checking for updates", "channel", channel)
+	m, err := Resolve(ctx, channel, PublicKeyHex)
+	if err != nil {
+		return false, err
+	}
+
+	current := version.FromContext(ctx)
+	if pinned != "" {
+		return false, nil // a pin holds the install at its current version
+	}
+	if m.Version == current || (current != "" && !belowMinVersion(current, m.Version)) {
+		return false, nil // m.Version isn't newer than what's running
+	}
+	if m.MinVersion != "" && belowMinVersion(current, m.MinVersion) {
+		return false, nil // this install is too old to jump straight to m.Version
+	}
+
+	id, err := installID(ctx)
+	if err != nil {
+		return false, err
+	}
+	return inRollout(id, m.RolloutPercent), nil
+}
+
+// Pin locks the install to v, preventing Check/Install from reporting or
+// applying any update until Unpin is called.
+func Pin(ctx context.Context, v string) error {
+	return config.Set(ctx, pinnedVersionKey, v)
+}
+
+// Unpin clears a previously set pin.
+func Unpin(ctx context.Context) error {
+	return config.Set(ctx, pinnedVersionKey, "")
+}
+
+// Rollback reinstalls the version recorded before the most recent Install,
+// if any. It only succeeds while the channel's current manifest is still
+// that version; otherwise it returns an error.
+func Rollback(ctx context.Context, dryRun bool) error {
+	db, ok := database.FromContext(ctx)
+	if !ok {
+		return fmt.Errorf("update: no database in context")
+	}
+	prevRaw, ok := db.Get(bucket, previousInstall)
+	if !ok {
+		return fmt.Errorf("no previous install recorded to roll back to")
+	}
+	prev := string(prevRaw)
+
+	channel, err := config.Get[string](ctx, "updateChannel")
+	if err != nil {
+		return fmt.Errorf("failed to read update channel: %w", err)
+	}
+	m, err := Resolve(ctx, channel, PublicKeyHex)
+	if err != nil {
+		return err
+	}
+	if m.Version != prev {
+		return fmt.Errorf("previous version %s is no longer published on channel %s", prev, channel)
+	}
+	return Install(ctx, m, dryRun)
+}
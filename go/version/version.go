@@ -0,0 +1,19 @@
+// Package version carries the build-time version string through context so
+// any package (update checks, support bundles, etc.) can report it without
+// an import cycle back to main.
+package version
+
+import "context"
+
+type ctxKey struct{}
+
+// IntoContext attaches the running binary's version string to ctx.
+func IntoContext(ctx context.Context, v string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, v)
+}
+
+// FromContext returns the version stored by IntoContext, or "" if none was set.
+func FromContext(ctx context.Context) string {
+	v, _ := ctx.Value(ctxKey{}).(string)
+	return v
+}